@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir for %s: %s", path, err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %s", path, err)
+	}
+}
+
+func TestIgnoreMatcher_NestedRepoAndNegationPrecedence(t *testing.T) {
+	root, err := ioutil.TempDir("", "ignore-matcher-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	writeFile(t, filepath.Join(root, ".gitignore"), "*.log\n")
+	writeFile(t, filepath.Join(root, "app.log"), "")
+	writeFile(t, filepath.Join(root, "sub", ".gitignore"), "!keep.log\n")
+	writeFile(t, filepath.Join(root, "sub", "app.log"), "")
+	writeFile(t, filepath.Join(root, "sub", "keep.log"), "")
+
+	m, err := newIgnoreMatcher(nil, "")
+	if err != nil {
+		t.Fatalf("newIgnoreMatcher returned error: %s", err)
+	}
+
+	cases := []struct {
+		path   string
+		ignore bool
+	}{
+		{filepath.Join(root, "app.log"), true},
+		{filepath.Join(root, "sub", "app.log"), true},
+		// A closer .gitignore's negation must win over the ancestor's rule.
+		{filepath.Join(root, "sub", "keep.log"), false},
+	}
+
+	for _, c := range cases {
+		if got := m.isIgnored(c.path); got != c.ignore {
+			t.Errorf("isIgnored(%s) = %v, want %v", c.path, got, c.ignore)
+		}
+	}
+}
+
+func TestIgnoreMatcher_DirectoryVsFilePattern(t *testing.T) {
+	root, err := ioutil.TempDir("", "ignore-matcher-dirfile-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	// "build/" is a directory-only pattern: it must ignore files under a
+	// "build" directory, but not a plain file that happens to be named "build".
+	writeFile(t, filepath.Join(root, ".gitignore"), "build/\n")
+	writeFile(t, filepath.Join(root, "build", "out.go"), "")
+	writeFile(t, filepath.Join(root, "other", "build"), "")
+
+	m, err := newIgnoreMatcher(nil, "")
+	if err != nil {
+		t.Fatalf("newIgnoreMatcher returned error: %s", err)
+	}
+
+	if !m.isIgnored(filepath.Join(root, "build", "out.go")) {
+		t.Errorf("expected file under build/ directory to be ignored")
+	}
+	if m.isIgnored(filepath.Join(root, "other", "build")) {
+		t.Errorf("expected a plain file named 'build' to not be ignored by the directory-only pattern")
+	}
+}
+
+func TestIgnoreMatcher_ExplicitPatternsAndIgnoreFile(t *testing.T) {
+	root, err := ioutil.TempDir("", "ignore-matcher-explicit-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	ignoreFile := filepath.Join(root, "custom-ignore")
+	writeFile(t, ignoreFile, "vendor/\n")
+	writeFile(t, filepath.Join(root, "vendor", "lib.go"), "")
+	writeFile(t, filepath.Join(root, "my-vendor-lib", "lib.go"), "")
+
+	m, err := newIgnoreMatcher([]string{""}, ignoreFile)
+	if err != nil {
+		t.Fatalf("newIgnoreMatcher returned error: %s", err)
+	}
+
+	if !m.isIgnored(filepath.Join(root, "vendor", "lib.go")) {
+		t.Errorf("expected vendor/lib.go to be ignored via -ignore-file")
+	}
+	if m.isIgnored(filepath.Join(root, "my-vendor-lib", "lib.go")) {
+		t.Errorf("expected my-vendor-lib/lib.go to survive glob matching(no substring false positive)")
+	}
+}
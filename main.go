@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/cheggaaa/pb/v3"
 	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	gitignore "github.com/sabhiram/go-gitignore"
 
+	"github.com/incu6us/goimports-reviser/v2/pkg/fsys"
 	"github.com/incu6us/goimports-reviser/v2/pkg/module"
 	"github.com/incu6us/goimports-reviser/v2/reviser"
 )
@@ -24,9 +32,16 @@ const (
 	removeUnusedImportsArg = "rm-unused"
 	setAliasArg            = "set-alias"
 	localPkgPrefixesArg    = "local"
+	companyPkgPrefixesArg  = "company-prefixes"
 	outputArg              = "output"
 	formatArg              = "format"
 	ignoreDirArg           = "ignore-dir"
+	jobsArg                = "jobs"
+	listArg                = "l"
+	diffArg                = "d"
+	overlayArg             = "overlay"
+	ignoreFileArg          = "ignore-file"
+	skipStdLoadArg         = "skip-std-load"
 )
 
 // Project build specific vars
@@ -40,9 +55,14 @@ var (
 	shouldRemoveUnusedImports *bool
 	shouldSetAlias            *bool
 	shouldFormat              *bool
+	shouldList                *bool
+	shouldDiff                *bool
+	shouldSkipStdLoad         *bool
 )
 
-var projectName, ignoreDir, filePath, dirPath, localPkgPrefixes, output string
+var projectName, ignoreDir, filePath, dirPath, localPkgPrefixes, companyPkgPrefixes, output, overlayPath, ignoreFilePath string
+
+var jobs int
 
 var ignoreDirs []string
 
@@ -52,7 +72,15 @@ func init() {
 		&ignoreDir,
 		ignoreDirArg,
 		"",
-		"ignore dir path to fix imports",
+		"ignore dir path to fix imports. Supports gitignore-style glob patterns(comma-separated). "+
+			"`.gitignore` files found while walking up from each processed path are honored automatically.",
+	)
+
+	flag.StringVar(
+		&ignoreFilePath,
+		ignoreFileArg,
+		"",
+		"Path to a custom gitignore-style ignore file, in addition to any `.gitignore` files discovered automatically. Optional parameter.",
 	)
 
 	flag.StringVar(
@@ -83,6 +111,14 @@ func init() {
 		"Local package prefixes which will be placed after 3rd-party group(if defined). Values should be comma-separated. Optional parameters.",
 	)
 
+	flag.StringVar(
+		&companyPkgPrefixes,
+		companyPkgPrefixesArg,
+		"",
+		"Company package prefixes which will be placed in their own group between 3rd-party and local groups(if defined). "+
+			"Values should be comma-separated. Optional parameter.",
+	)
+
 	flag.StringVar(
 		&output,
 		outputArg,
@@ -109,6 +145,40 @@ func init() {
 		"Option will perform additional formatting. Optional parameter.",
 	)
 
+	flag.IntVar(
+		&jobs,
+		jobsArg,
+		runtime.NumCPU(),
+		"Number of files to process concurrently. Optional parameter.",
+	)
+
+	shouldList = flag.Bool(
+		listArg,
+		false,
+		"List files whose formatting differs(no rewrite). Exit status is non-zero if any file would change. Optional parameter.",
+	)
+
+	shouldDiff = flag.Bool(
+		diffArg,
+		false,
+		"Print a unified diff instead of rewriting files. Exit status is non-zero if any file would change. Optional parameter.",
+	)
+
+	flag.StringVar(
+		&overlayPath,
+		overlayArg,
+		"",
+		"Path to a JSON overlay file(same shape as used by cmd/go and gopls) mapping real file paths to "+
+			"the virtual content that should be used instead. Optional parameter.",
+	)
+
+	shouldSkipStdLoad = flag.Bool(
+		skipStdLoadArg,
+		false,
+		"Skip the one-shot `golang.org/x/tools/go/packages` load of the standard library package set and fall back to "+
+			"the built-in heuristic for std classification. Useful in hermetic environments without a Go toolchain. Optional parameter.",
+	)
+
 	if Tag != "" {
 		shouldShowVersion = flag.Bool(
 			versionArg,
@@ -145,7 +215,15 @@ func main() {
 		printVersion()
 		return
 	}
-	err := validateRequiredParam(filePath)
+
+	ignoreMatcher, err := newIgnoreMatcher(ignoreDirs, ignoreFilePath)
+	if err != nil {
+		fmt.Printf("%s\n\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+
+	err = validateRequiredParam(filePath)
 	errDir := validateDir(dirPath)
 	if err != nil && errDir != nil {
 		fmt.Printf("%s . and -dir-path %s\n\n", err, errDir)
@@ -153,7 +231,14 @@ func main() {
 		os.Exit(1)
 	}
 
-	projectName, err := determineProjectName(projectName, filePath)
+	fs, err := fsys.Load(overlayPath)
+	if err != nil {
+		fmt.Printf("%s\n\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+
+	projectName, err := determineProjectName(fs, projectName, filePath)
 	if err != nil {
 		fmt.Printf("%s\n\n", err)
 		printUsage()
@@ -173,11 +258,30 @@ func main() {
 		options = append(options, reviser.OptionFormat)
 	}
 
+	if shouldSkipStdLoad != nil && *shouldSkipStdLoad {
+		options = append(options, reviser.OptionSkipStdLoad)
+	}
+
+	if shouldDiff != nil && *shouldDiff {
+		output = "diff"
+	} else if shouldList != nil && *shouldList {
+		output = "list"
+	}
+
+	var anyChanged int32
+
 	var count int64 = 0
 	// create and start new bar
 	bar := pb.StartNew(int(count))
+	var barMu sync.Mutex
+	var outMu sync.Mutex
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var fatalErr atomic.Value
 	executor := func(filePath string) {
-		if isIgnore(filePath) {
+		if ignoreMatcher.isIgnored(filePath) {
 			return
 		}
 
@@ -185,37 +289,74 @@ func main() {
 			return
 		}
 
-		formattedOutput, hasChange, err := reviser.Execute(projectName, filePath, localPkgPrefixes, options...)
-		count++
-		bar.SetCurrent(count - 2)
-		bar.SetTotal(count)
+		formattedOutput, hasChange, err := reviser.Execute(fs, projectName, filePath, localPkgPrefixes, companyPkgPrefixes, options...)
+		current := atomic.AddInt64(&count, 1)
+		barMu.Lock()
+		bar.SetCurrent(current - 2)
+		bar.SetTotal(current)
 		bar.Increment()
+		barMu.Unlock()
 		if err != nil {
-			log.Fatalf("%+v", errors.WithStack(err))
+			fatalErr.Store(errors.WithStack(err))
+			cancel()
+			return
 		}
 
-		if output == "stdout" {
+		switch output {
+		case "stdout":
+			outMu.Lock()
 			fmt.Print(string(formattedOutput))
-		} else if output == "file" {
+			outMu.Unlock()
+		case "file":
 			if !hasChange {
 				return
 			}
 			fmt.Println(filePath)
-			if err := ioutil.WriteFile(filePath, formattedOutput, 0644); err != nil {
-				log.Fatalf("failed to write fixed result to file(%s): %+v", filePath, errors.WithStack(err))
+			if err := writeFormattedFile(filePath, formattedOutput); err != nil {
+				fatalErr.Store(errors.WithStack(err))
+				cancel()
+				return
+			}
+		case "list":
+			if !hasChange {
+				return
+			}
+			atomic.StoreInt32(&anyChanged, 1)
+			fmt.Println(filePath)
+		case "diff":
+			if !hasChange {
+				return
+			}
+			atomic.StoreInt32(&anyChanged, 1)
+			original, err := fs.ReadFile(filePath)
+			if err != nil {
+				fatalErr.Store(errors.WithStack(err))
+				cancel()
+				return
+			}
+			diff, err := unifiedDiff(filePath, original, formattedOutput)
+			if err != nil {
+				fatalErr.Store(errors.WithStack(err))
+				cancel()
+				return
 			}
-		} else {
-			log.Fatalf(`invalid output "%s" specified`, output)
+			outMu.Lock()
+			fmt.Print(diff)
+			outMu.Unlock()
+		default:
+			fatalErr.Store(errors.Errorf(`invalid output "%s" specified`, output))
+			cancel()
+			return
 		}
 	}
 
 	switch {
 	case dirPath == "./...":
-		load("./", executor)
+		load(ctx, fs, "./", executor)
 	case dirPath == "./":
-		load(dirPath, executor)
+		load(ctx, fs, dirPath, executor)
 	case dirPath != "":
-		load(dirPath, executor)
+		load(ctx, fs, dirPath, executor)
 	case dirPath == "":
 		executor(filePath)
 	}
@@ -223,18 +364,157 @@ func main() {
 	executor(filePath)
 	// finish bar
 	bar.Finish()
+
+	if err, ok := fatalErr.Load().(error); ok {
+		log.Fatalf("%+v", err)
+	}
+
+	if (output == "list" || output == "diff") && atomic.LoadInt32(&anyChanged) != 0 {
+		os.Exit(1)
+	}
+}
+
+// ignoreMatcher matches paths against gitignore-style patterns: the explicit
+// patterns passed on the command line plus any `.gitignore` files discovered
+// by walking up from each processed path. Discovered `.gitignore` files are
+// cached per directory since the matcher is shared across worker goroutines.
+type ignoreMatcher struct {
+	explicit *gitignore.GitIgnore
+
+	mu       sync.Mutex
+	dirCache map[string]*gitignore.GitIgnore
 }
 
-func isIgnore(path string) bool {
-	for _, val := range ignoreDirs {
-		if strings.Contains(path, val) {
-			fmt.Println("ignore dir: ", path)
-			return true
+func newIgnoreMatcher(patterns []string, ignoreFile string) (*ignoreMatcher, error) {
+	var lines []string
+	for _, pattern := range patterns {
+		if pattern != "" {
+			lines = append(lines, pattern)
 		}
 	}
+
+	if ignoreFile != "" {
+		content, err := ioutil.ReadFile(ignoreFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read ignore file(%s)", ignoreFile)
+		}
+		lines = append(lines, strings.Split(string(content), "\n")...)
+	}
+
+	var explicit *gitignore.GitIgnore
+	if len(lines) > 0 {
+		explicit = gitignore.CompileIgnoreLines(lines...)
+	}
+
+	return &ignoreMatcher{explicit: explicit, dirCache: make(map[string]*gitignore.GitIgnore)}, nil
+}
+
+// isIgnored reports whether path should be skipped.
+func (m *ignoreMatcher) isIgnored(path string) bool {
+	if m.explicit != nil && m.explicit.MatchesPath(path) {
+		fmt.Println("ignore path: ", path)
+		return true
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	absPath = filepath.ToSlash(absPath)
+
+	if gi := m.discoveredFor(filepath.Dir(absPath)); gi != nil && gi.MatchesPath(absPath) {
+		fmt.Println("ignore path: ", path)
+		return true
+	}
+
 	return false
 }
 
+// discoveredFor returns a single GitIgnore combining every `.gitignore` from
+// the filesystem root down to dir, with each file's patterns rewritten to be
+// anchored at its own directory and applied in root-to-leaf order - mirroring
+// git's own precedence, where a closer `.gitignore` is evaluated after (and
+// can override/negate) a farther ancestor's rule for the same path. The
+// result is cached per directory since the matcher is shared across worker
+// goroutines that commonly process many files from the same directory.
+func (m *ignoreMatcher) discoveredFor(dir string) *gitignore.GitIgnore {
+	m.mu.Lock()
+	if gi, ok := m.dirCache[dir]; ok {
+		m.mu.Unlock()
+		return gi
+	}
+	m.mu.Unlock()
+
+	type level struct {
+		dir   string
+		lines []string
+	}
+
+	var levels []level
+	for d := dir; ; {
+		if content, err := ioutil.ReadFile(filepath.Join(d, ".gitignore")); err == nil {
+			levels = append(levels, level{dir: filepath.ToSlash(d), lines: strings.Split(string(content), "\n")})
+		}
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+
+	// levels was collected nearest-to-farthest; walk it back-to-front so the
+	// combined pattern list is farthest-ancestor-first, nearest-directory-last.
+	var combined []string
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, line := range levels[i].lines {
+			if pattern, ok := anchorPattern(levels[i].dir, line); ok {
+				combined = append(combined, pattern)
+			}
+		}
+	}
+
+	var gi *gitignore.GitIgnore
+	if len(combined) > 0 {
+		gi = gitignore.CompileIgnoreLines(combined...)
+	}
+
+	m.mu.Lock()
+	m.dirCache[dir] = gi
+	m.mu.Unlock()
+
+	return gi
+}
+
+// anchorPattern rewrites a single `.gitignore` line found in dir into a
+// pattern anchored at dir, suitable for combining with patterns from other
+// directories into one GitIgnore matched against absolute paths. Blank lines
+// and comments are dropped.
+func anchorPattern(dir, line string) (string, bool) {
+	trimmed := strings.TrimSpace(strings.TrimRight(line, "\r"))
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", false
+	}
+
+	negate := strings.HasPrefix(trimmed, "!")
+	if negate {
+		trimmed = trimmed[1:]
+	}
+
+	var anchored string
+	if strings.HasPrefix(trimmed, "/") {
+		anchored = dir + trimmed
+	} else {
+		// Not anchored to dir itself: matches at any depth under it.
+		anchored = dir + "/**/" + trimmed
+	}
+
+	if negate {
+		anchored = "!" + anchored
+	}
+	return anchored, true
+}
+
 func validateDir(p string) error {
 	if p == "./..." {
 		return nil
@@ -262,18 +542,122 @@ func pwd() string {
 	return path
 }
 
-func load(rootPath string, executor func(string)) {
-	err := filepath.Walk(
+// load walks rootPath and feeds discovered file paths to a bounded pool of
+// workers (sized by the -jobs flag) which run executor concurrently. Walking
+// stops early once ctx is cancelled, e.g. after the first fatal error.
+func load(ctx context.Context, fs *fsys.FS, rootPath string, executor func(string)) {
+	paths := make(chan string)
+
+	var wg sync.WaitGroup
+	workers := jobs
+	if workers < 1 {
+		workers = 1
+	}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				executor(path)
+			}
+		}()
+	}
+
+	err := fs.Walk(
 		rootPath,
 		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			if info.IsDir() {
 				return nil
 			}
-			executor(path)
-			return err
+			select {
+			case paths <- path:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return nil
 		},
 	)
-	errorCheck(err)
+	close(paths)
+	wg.Wait()
+
+	if err != nil && err != context.Canceled {
+		errorCheck(err)
+	}
+}
+
+// writeFormattedFile writes formattedOutput back to filePath, preserving the
+// original file's permissions. If filePath is a symlink, it resolves it and
+// writes to the link's target instead of replacing the link itself.
+func writeFormattedFile(filePath string, formattedOutput []byte) error {
+	perm := os.FileMode(0644)
+	if fi, err := os.Lstat(filePath); err == nil {
+		perm = fi.Mode() & os.ModePerm
+
+		if fi.Mode()&os.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(filePath)
+			if err != nil {
+				return err
+			}
+			filePath = target
+
+			if targetFi, err := os.Stat(filePath); err == nil {
+				perm = targetFi.Mode() & os.ModePerm
+			}
+		}
+	}
+
+	return ioutil.WriteFile(filePath, formattedOutput, perm)
+}
+
+// unifiedDiff returns a unified diff between original and formatted, labeled
+// with filePath. It shells out to "diff -u" when available and falls back to
+// an in-process unified diff from go-difflib otherwise.
+func unifiedDiff(filePath string, original, formatted []byte) (string, error) {
+	if _, err := exec.LookPath("diff"); err == nil {
+		origFile, err := ioutil.TempFile("", "goimports-reviser-orig-*.go")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(origFile.Name())
+
+		newFile, err := ioutil.TempFile("", "goimports-reviser-new-*.go")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(newFile.Name())
+
+		if err := ioutil.WriteFile(origFile.Name(), original, 0600); err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(newFile.Name(), formatted, 0600); err != nil {
+			return "", err
+		}
+
+		out, err := exec.Command("diff", "-u", origFile.Name(), newFile.Name()).CombinedOutput()
+		// diff exits with status 1 when the files differ, which is expected here.
+		if err != nil && len(out) == 0 {
+			return "", err
+		}
+
+		diffText := string(out)
+		diffText = strings.Replace(diffText, origFile.Name(), filePath+".orig", 1)
+		diffText = strings.Replace(diffText, newFile.Name(), filePath, 1)
+		return diffText, nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(original)),
+		B:        difflib.SplitLines(string(formatted)),
+		FromFile: filePath + ".orig",
+		ToFile:   filePath,
+		Context:  3,
+	})
 }
 
 func isFormatFile(p string) bool {
@@ -284,14 +668,14 @@ func isFormatFile(p string) bool {
 	return false
 }
 
-func determineProjectName(projectName, filePath string) (string, error) {
+func determineProjectName(fs *fsys.FS, projectName, filePath string) (string, error) {
 	if projectName == "" {
-		projectRootPath, err := module.GoModRootPath(filePath)
+		projectRootPath, err := module.GoModRootPath(fs, filePath)
 		if err != nil {
 			return "", err
 		}
 
-		moduleName, err := module.Name(projectRootPath)
+		moduleName, err := module.Name(fs, projectRootPath)
 		if err != nil {
 			return "", err
 		}
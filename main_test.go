@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFormattedFile_PreservesMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write-formatted-file-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	filePath := filepath.Join(dir, "input.go")
+	if err := ioutil.WriteFile(filePath, []byte("package main\n"), 0600); err != nil {
+		t.Fatalf("failed to write input file: %s", err)
+	}
+
+	if err := writeFormattedFile(filePath, []byte("package main\n\nfunc main() {}\n")); err != nil {
+		t.Fatalf("writeFormattedFile returned error: %s", err)
+	}
+
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("failed to stat output file: %s", err)
+	}
+
+	if perm := fi.Mode() & os.ModePerm; perm != 0600 {
+		t.Fatalf("expected mode 0600 to be preserved, got %o", perm)
+	}
+}
+
+func TestWriteFormattedFile_ResolvesSymlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write-formatted-file-symlink-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	targetPath := filepath.Join(dir, "target.go")
+	if err := ioutil.WriteFile(targetPath, []byte("package main\n"), 0640); err != nil {
+		t.Fatalf("failed to write target file: %s", err)
+	}
+
+	linkPath := filepath.Join(dir, "link.go")
+	if err := os.Symlink(targetPath, linkPath); err != nil {
+		t.Skipf("symlinks not supported on this platform: %s", err)
+	}
+
+	if err := writeFormattedFile(linkPath, []byte("package main\n\nfunc main() {}\n")); err != nil {
+		t.Fatalf("writeFormattedFile returned error: %s", err)
+	}
+
+	if _, err := os.Lstat(linkPath); err != nil || !isSymlink(linkPath) {
+		t.Fatalf("expected the symlink itself to remain in place")
+	}
+
+	content, err := ioutil.ReadFile(targetPath)
+	if err != nil {
+		t.Fatalf("failed to read symlink target: %s", err)
+	}
+	if string(content) != "package main\n\nfunc main() {}\n" {
+		t.Fatalf("expected the symlink target to contain the formatted output, got %q", content)
+	}
+
+	fi, err := os.Lstat(targetPath)
+	if err != nil {
+		t.Fatalf("failed to stat symlink target: %s", err)
+	}
+	if perm := fi.Mode() & os.ModePerm; perm != 0640 {
+		t.Fatalf("expected mode 0640 to be preserved on the symlink target, got %o", perm)
+	}
+}
+
+func isSymlink(path string) bool {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeSymlink != 0
+}
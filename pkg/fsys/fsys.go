@@ -0,0 +1,91 @@
+// Package fsys provides an overlay-aware filesystem abstraction used by the
+// reviser to read Go source and go.mod files. It lets callers (editors,
+// build systems, LSP servers) redirect reads to in-memory or temporary
+// content without materializing it at the real path.
+package fsys
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// FS resolves file reads through an optional overlay before falling back to
+// the OS filesystem. The zero value is a valid FS with no overlay.
+type FS struct {
+	replace map[string]string
+}
+
+// overlayJSON mirrors the overlay shape used by cmd/go and gopls:
+// {"Replace": {"/abs/path/foo.go": "/tmp/overlay/foo.go"}}.
+type overlayJSON struct {
+	Replace map[string]string `json:"Replace"`
+}
+
+// New returns an FS with no overlay; all reads fall through to the OS.
+func New() *FS {
+	return &FS{}
+}
+
+// Load reads the JSON overlay file at path and returns an FS backed by it.
+func Load(path string) (*FS, error) {
+	if path == "" {
+		return New(), nil
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read overlay file(%s)", path)
+	}
+
+	var overlay overlayJSON
+	if err := json.Unmarshal(raw, &overlay); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse overlay file(%s)", path)
+	}
+
+	return &FS{replace: overlay.Replace}, nil
+}
+
+// resolve returns the real path to read for the given path, following the
+// overlay mapping when present.
+func (fs *FS) resolve(path string) string {
+	if fs == nil || fs.replace == nil {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	if target, ok := fs.replace[abs]; ok {
+		return target
+	}
+
+	return path
+}
+
+// Open opens the named file, consulting the overlay first.
+func (fs *FS) Open(path string) (*os.File, error) {
+	return os.Open(fs.resolve(path))
+}
+
+// ReadFile reads the named file, consulting the overlay first.
+func (fs *FS) ReadFile(path string) ([]byte, error) {
+	return ioutil.ReadFile(fs.resolve(path))
+}
+
+// Stat stats the named file, consulting the overlay first.
+func (fs *FS) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(fs.resolve(path))
+}
+
+// Walk walks the file tree rooted at root, consulting the overlay for the
+// root path itself only. Entries are visited as they exist on disk; overlay
+// entries with no file on disk are not added to the walk.
+func (fs *FS) Walk(root string, walkFn filepath.WalkFunc) error {
+	return filepath.Walk(fs.resolve(root), walkFn)
+}
@@ -0,0 +1,111 @@
+// Package module resolves a Go project's module root and module name from
+// its go.mod file.
+package module
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/incu6us/goimports-reviser/v2/pkg/fsys"
+)
+
+const goModFilename = "go.mod"
+
+// rootCache and nameCache memoize GoModRootPath results per directory and
+// Name results per root path, each guarded by its own mutex so concurrent
+// callers can share them: repeated lookups for files in the same directory
+// do not re-walk the tree or re-parse go.mod.
+var (
+	rootCacheMu sync.Mutex
+	rootCache   = make(map[string]string)
+
+	nameCacheMu sync.Mutex
+	nameCache   = make(map[string]string)
+)
+
+// GoModRootPath walks up from filePath's directory until it finds a
+// directory containing a go.mod file, and returns that directory.
+func GoModRootPath(fs *fsys.FS, filePath string) (string, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path for %q: %w", filePath, err)
+	}
+
+	dir := filepath.Dir(absFilePath)
+	if fi, err := fs.Stat(absFilePath); err == nil && fi.IsDir() {
+		dir = absFilePath
+	}
+
+	rootCacheMu.Lock()
+	if root, ok := rootCache[dir]; ok {
+		rootCacheMu.Unlock()
+		return root, nil
+	}
+	rootCacheMu.Unlock()
+
+	root, err := findGoModRoot(fs, dir)
+	if err != nil {
+		return "", err
+	}
+
+	rootCacheMu.Lock()
+	rootCache[dir] = root
+	rootCacheMu.Unlock()
+
+	return root, nil
+}
+
+func findGoModRoot(fs *fsys.FS, dir string) (string, error) {
+	for {
+		if _, err := fs.Stat(filepath.Join(dir, goModFilename)); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%s not found starting from %q", goModFilename, dir)
+		}
+		dir = parent
+	}
+}
+
+// Name returns the module name declared by the go.mod file in rootPath.
+func Name(fs *fsys.FS, rootPath string) (string, error) {
+	nameCacheMu.Lock()
+	if name, ok := nameCache[rootPath]; ok {
+		nameCacheMu.Unlock()
+		return name, nil
+	}
+	nameCacheMu.Unlock()
+
+	goModPath := filepath.Join(rootPath, goModFilename)
+	f, err := fs.Open(goModPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", goModPath, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "module ") {
+			continue
+		}
+
+		name := strings.TrimSpace(strings.TrimPrefix(line, "module "))
+
+		nameCacheMu.Lock()
+		nameCache[rootPath] = name
+		nameCacheMu.Unlock()
+
+		return name, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", goModPath, err)
+	}
+
+	return "", fmt.Errorf("module declaration not found in %s", goModPath)
+}
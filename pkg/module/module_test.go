@@ -0,0 +1,52 @@
+package module
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/incu6us/goimports-reviser/v2/pkg/fsys"
+)
+
+func TestGoModRootPathAndName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "module-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/example/project\n\ngo 1.17\n"), 0644); err != nil {
+		t.Fatalf("failed to write go.mod: %s", err)
+	}
+
+	pkgDir := filepath.Join(dir, "pkg", "sub")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatalf("failed to create nested dir: %s", err)
+	}
+	filePath := filepath.Join(pkgDir, "file.go")
+	if err := ioutil.WriteFile(filePath, []byte("package sub\n"), 0644); err != nil {
+		t.Fatalf("failed to write file.go: %s", err)
+	}
+
+	fs := fsys.New()
+
+	// Call twice to exercise both the cache miss and cache hit path.
+	for i := 0; i < 2; i++ {
+		root, err := GoModRootPath(fs, filePath)
+		if err != nil {
+			t.Fatalf("GoModRootPath returned error: %s", err)
+		}
+		if root != dir {
+			t.Fatalf("expected root %q, got %q", dir, root)
+		}
+
+		name, err := Name(fs, root)
+		if err != nil {
+			t.Fatalf("Name returned error: %s", err)
+		}
+		if name != "github.com/example/project" {
+			t.Fatalf("expected module name %q, got %q", "github.com/example/project", name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package reviser
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var (
+	stdOnce sync.Once
+	stdPkgs map[string]struct{}
+)
+
+// loadStd does the one-shot `go/packages` load of the standard library's
+// package set. Guarded by stdOnce so repeated reviser.Execute calls -
+// including ones made concurrently by the CLI's worker pool - reuse the
+// same result instead of re-invoking the Go toolchain per file.
+func loadStd() {
+	pkgs, err := packages.Load(nil, "std")
+	if err != nil {
+		// stdPkgs stays nil; isStd falls back to the heuristic below.
+		return
+	}
+
+	loaded := make(map[string]struct{}, len(pkgs))
+	for _, pkg := range pkgs {
+		loaded[pkg.PkgPath] = struct{}{}
+	}
+	stdPkgs = loaded
+}
+
+// isStd reports whether path is a standard-library import. It's authoritative
+// when the `go/packages` load of "std" succeeds, and falls back to the
+// conventional heuristic (no dot in the first path component) when it fails
+// or skipStdLoad is set, e.g. in a hermetic environment with no toolchain.
+func isStd(path string, skipStdLoad bool) bool {
+	if path == "" {
+		return false
+	}
+
+	if skipStdLoad {
+		return isStdHeuristic(path)
+	}
+
+	stdOnce.Do(loadStd)
+
+	if stdPkgs != nil {
+		_, ok := stdPkgs[path]
+		return ok
+	}
+
+	return isStdHeuristic(path)
+}
+
+func isStdHeuristic(path string) bool {
+	first := path
+	if idx := strings.IndexByte(path, '/'); idx >= 0 {
+		first = path[:idx]
+	}
+
+	return !strings.Contains(first, ".")
+}
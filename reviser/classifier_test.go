@@ -0,0 +1,17 @@
+package reviser
+
+import "testing"
+
+func TestIsStd_SkipStdLoadIgnoresWarmCache(t *testing.T) {
+	// Warm the package-level std cache with a real load first, simulating a
+	// long-lived process that has already served a request without
+	// OptionSkipStdLoad.
+	isStd("fmt", false)
+
+	// A path the heuristic (no dot in the first segment) considers std, but
+	// which cannot be in the real std set. skipStdLoad must force the
+	// heuristic regardless of what's already cached.
+	if !isStd("nodothost/pkg", true) {
+		t.Fatalf("expected skipStdLoad to use the heuristic even with a warm cache")
+	}
+}
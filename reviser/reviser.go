@@ -0,0 +1,348 @@
+// Package reviser implements the import-revising logic used by the CLI: it
+// parses a Go source file, groups its imports into std/third-party/company/
+// local buckets, sorts each group, and optionally removes unused imports,
+// sets aliases for major-version-suffixed packages, and re-formats the file.
+package reviser
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/incu6us/goimports-reviser/v2/pkg/fsys"
+)
+
+// Option toggles optional revising behavior passed to Execute.
+type Option int
+
+const (
+	// OptionRemoveUnusedImports drops imports that are not referenced
+	// anywhere else in the file.
+	OptionRemoveUnusedImports Option = iota
+
+	// OptionUseAliasForVersionSuffix sets an explicit alias for imports
+	// whose last path element is a major-version suffix (e.g. "v2"), using
+	// the package name that precedes it.
+	OptionUseAliasForVersionSuffix
+
+	// OptionFormat additionally runs the revised output through
+	// format.Source after the regrouped import block is spliced in.
+	OptionFormat
+
+	// OptionSkipStdLoad skips the one-shot `go/packages` load of the
+	// standard library package set and falls back to the heuristic std
+	// classifier, e.g. in a hermetic environment with no Go toolchain.
+	OptionSkipStdLoad
+)
+
+// Options is the set of Option values requested by the caller.
+type Options []Option
+
+func (o Options) has(opt Option) bool {
+	for _, v := range o {
+		if v == opt {
+			return true
+		}
+	}
+	return false
+}
+
+var versionSuffixRe = regexp.MustCompile(`^(.*/)v([2-9]|[1-9][0-9]+)$`)
+
+// Execute revises the import block of the Go source file at filePath and
+// returns the formatted output along with whether it differs from the
+// original content. Reads go through fs, so callers can run against an
+// overlay or otherwise unmaterialized content.
+func Execute(fs *fsys.FS, projectName, filePath, localPkgPrefixes, companyPkgPrefixes string, options ...Option) ([]byte, bool, error) {
+	opts := Options(options)
+
+	original, err := fs.ReadFile(filePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read file(%s): %w", filePath, err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, original, parser.ParseComments)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse file(%s): %w", filePath, err)
+	}
+
+	decl := importDecl(file)
+	if decl == nil || decl.Lparen == token.NoPos {
+		// Nothing to regroup: no import block, or a single bare import.
+		return original, false, nil
+	}
+
+	if opts.has(OptionRemoveUnusedImports) {
+		removeUnusedImports(file, decl)
+	}
+
+	localPrefixes := append(splitPrefixes(projectName), splitPrefixes(localPkgPrefixes)...)
+	companyPrefixes := splitPrefixes(companyPkgPrefixes)
+
+	block, err := renderImportBlock(decl, localPrefixes, companyPrefixes, opts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	startOffset := fset.Position(decl.Lparen).Offset + len("(")
+	endOffset := fset.Position(decl.Rparen).Offset
+
+	var buf bytes.Buffer
+	buf.Write(original[:startOffset])
+	buf.WriteString("\n")
+	buf.WriteString(block)
+	buf.Write(original[endOffset:])
+
+	formatted := buf.Bytes()
+	if opts.has(OptionFormat) {
+		if gofmted, err := format.Source(formatted); err == nil {
+			formatted = gofmted
+		}
+	}
+
+	return formatted, !bytes.Equal(original, formatted), nil
+}
+
+// importDecl returns the first import declaration block in file, if any.
+func importDecl(file *ast.File) *ast.GenDecl {
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			return genDecl
+		}
+	}
+	return nil
+}
+
+// importGroup is one of the four blank-line-separated buckets an import can
+// fall into. Group order below (std, third-party, company, local) matches
+// the order they're rendered in.
+type importGroup int
+
+const (
+	groupStd importGroup = iota
+	groupThirdParty
+	groupCompany
+	groupLocal
+)
+
+// classify returns which group path belongs to. std is checked first, then
+// company prefixes, then local prefixes, with third-party as the default -
+// so a company prefix that overlaps a local prefix always wins as company.
+func classify(path string, localPrefixes, companyPrefixes []string, skipStdLoad bool) importGroup {
+	if isStd(path, skipStdLoad) {
+		return groupStd
+	}
+	if matchesAnyPrefix(path, companyPrefixes) {
+		return groupCompany
+	}
+	if matchesAnyPrefix(path, localPrefixes) {
+		return groupLocal
+	}
+	return groupThirdParty
+}
+
+func matchesAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			continue
+		}
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func splitPrefixes(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	var prefixes []string
+	for _, p := range strings.Split(raw, ",") {
+		if p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
+}
+
+// renderImportSpec holds the pieces of an *ast.ImportSpec needed to render
+// it back out, decoupled from its original source position.
+type renderImportSpec struct {
+	path    string
+	alias   string
+	doc     string
+	comment string
+}
+
+func renderImportBlock(decl *ast.GenDecl, localPrefixes, companyPrefixes []string, opts Options) (string, error) {
+	skipStdLoad := opts.has(OptionSkipStdLoad)
+	groups := make([][]renderImportSpec, groupLocal+1)
+
+	for _, spec := range decl.Specs {
+		imp, ok := spec.(*ast.ImportSpec)
+		if !ok {
+			continue
+		}
+
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return "", fmt.Errorf("failed to unquote import path(%s): %w", imp.Path.Value, err)
+		}
+
+		alias := ""
+		if imp.Name != nil {
+			alias = imp.Name.Name
+		} else if opts.has(OptionUseAliasForVersionSuffix) {
+			alias = aliasForVersionSuffix(path)
+		}
+
+		doc := ""
+		if imp.Doc != nil {
+			doc = imp.Doc.Text()
+		}
+
+		comment := ""
+		if imp.Comment != nil {
+			comment = imp.Comment.Text()
+		}
+
+		group := classify(path, localPrefixes, companyPrefixes, skipStdLoad)
+		groups[group] = append(groups[group], renderImportSpec{
+			path:    path,
+			alias:   alias,
+			doc:     strings.TrimRight(doc, "\n"),
+			comment: strings.TrimRight(comment, "\n"),
+		})
+	}
+
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool { return group[i].path < group[j].path })
+	}
+
+	var lines []string
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		for _, imp := range group {
+			for _, docLine := range strings.Split(imp.doc, "\n") {
+				if docLine == "" {
+					continue
+				}
+				lines = append(lines, "\t// "+docLine)
+			}
+			lines = append(lines, renderImportLine(imp))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func renderImportLine(imp renderImportSpec) string {
+	var b strings.Builder
+	b.WriteByte('\t')
+	if imp.alias != "" {
+		b.WriteString(imp.alias)
+		b.WriteByte(' ')
+	}
+	b.WriteString(strconv.Quote(imp.path))
+	if imp.comment != "" {
+		b.WriteString(" // ")
+		b.WriteString(imp.comment)
+	}
+	return b.String()
+}
+
+// aliasForVersionSuffix returns the package name to alias an import with,
+// when its last path element is a major-version suffix like "v2", e.g.
+// "github.com/go-pg/pg/v9" aliases as "pg".
+func aliasForVersionSuffix(path string) string {
+	matches := versionSuffixRe.FindStringSubmatch(path)
+	if matches == nil {
+		return ""
+	}
+
+	base := strings.TrimSuffix(matches[1], "/")
+	segments := strings.Split(base, "/")
+	return segments[len(segments)-1]
+}
+
+// removeUnusedImports drops import specs whose package identifier is never
+// referenced elsewhere in file. Blank ("_") and dot (".") imports are always
+// kept, since their use can't be determined syntactically.
+func removeUnusedImports(file *ast.File, decl *ast.GenDecl) {
+	used := usedPackageIdents(file, decl)
+
+	kept := decl.Specs[:0]
+	for _, spec := range decl.Specs {
+		imp, ok := spec.(*ast.ImportSpec)
+		if !ok {
+			kept = append(kept, spec)
+			continue
+		}
+
+		name := importedName(imp)
+		if name == "_" || name == "." || used[name] {
+			kept = append(kept, spec)
+		}
+	}
+	decl.Specs = kept
+}
+
+// usedPackageIdents collects the identifiers used as a selector qualifier
+// (pkg.Foo) anywhere in file outside of the import declaration itself.
+func usedPackageIdents(file *ast.File, importDecl *ast.GenDecl) map[string]bool {
+	used := make(map[string]bool)
+
+	for _, decl := range file.Decls {
+		if decl == ast.Decl(importDecl) {
+			continue
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	return used
+}
+
+// importedName returns the identifier other code in the file would use to
+// refer to this import: its alias if set, otherwise the conventional
+// package name derived from its last path element - skipping a trailing
+// major-version suffix (e.g. "github.com/go-pg/pg/v9" is referred to as
+// "pg", not "v9"), the same way aliasForVersionSuffix does.
+func importedName(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+
+	path, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		return ""
+	}
+
+	if name := aliasForVersionSuffix(path); name != "" {
+		return name
+	}
+
+	segments := strings.Split(path, "/")
+	return segments[len(segments)-1]
+}
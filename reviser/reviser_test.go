@@ -0,0 +1,157 @@
+package reviser
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/incu6us/goimports-reviser/v2/pkg/fsys"
+)
+
+func writeTempGoFile(t *testing.T, content string) string {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "reviser-test-*.go")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("failed to write temp file: %s", err)
+	}
+
+	t.Cleanup(func() { os.Remove(f.Name()) })
+	return f.Name()
+}
+
+const sourceWithOverlappingPrefixes = `package main
+
+import (
+	"fmt"
+
+	"github.com/co/localteam/pkg"
+	"github.com/co/pkg"
+	"github.com/third/party"
+)
+
+func main() {
+	fmt.Println(pkg.Foo, party.Bar)
+}
+`
+
+func TestExecute_OverlappingCompanyAndLocalPrefixes(t *testing.T) {
+	filePath := writeTempGoFile(t, sourceWithOverlappingPrefixes)
+
+	// "github.com/co" is both the company prefix and a literal prefix of
+	// the local prefix "github.com/co/localteam" - company must win for
+	// every import under "github.com/co", including "github.com/co/localteam/pkg".
+	formatted, hasChange, err := Execute(
+		fsys.New(),
+		"github.com/example/project",
+		filePath,
+		"github.com/co/localteam",
+		"github.com/co",
+	)
+	if err != nil {
+		t.Fatalf("Execute returned error: %s", err)
+	}
+	if !hasChange {
+		t.Fatalf("expected imports to be regrouped")
+	}
+
+	out := string(formatted)
+
+	companyIdx := strings.Index(out, `"github.com/co/pkg"`)
+	companyOverlapIdx := strings.Index(out, `"github.com/co/localteam/pkg"`)
+	thirdPartyIdx := strings.Index(out, `"github.com/third/party"`)
+
+	if companyIdx == -1 || companyOverlapIdx == -1 || thirdPartyIdx == -1 {
+		t.Fatalf("expected all imports to survive, got:\n%s", out)
+	}
+
+	// both company-prefixed imports must be grouped together, after 3rd-party.
+	if !(thirdPartyIdx < companyIdx && thirdPartyIdx < companyOverlapIdx) {
+		t.Fatalf("expected 3rd-party group before company group, got:\n%s", out)
+	}
+	if !strings.Contains(out, "\"github.com/co/localteam/pkg\"\n\t\"github.com/co/pkg\"") {
+		t.Fatalf("expected company-prefixed imports in one group, got:\n%s", out)
+	}
+}
+
+func TestExecute_UseAliasForVersionSuffix(t *testing.T) {
+	filePath := writeTempGoFile(t, `package main
+
+import (
+	"github.com/go-pg/pg/v9"
+)
+
+func main() {
+	_ = pg.Options{}
+}
+`)
+
+	formatted, _, err := Execute(fsys.New(), "github.com/example/project", filePath, "", "", OptionUseAliasForVersionSuffix)
+	if err != nil {
+		t.Fatalf("Execute returned error: %s", err)
+	}
+
+	if !strings.Contains(string(formatted), `pg "github.com/go-pg/pg/v9"`) {
+		t.Fatalf(`expected alias "pg" for versioned import, got:\n%s`, formatted)
+	}
+}
+
+func TestExecute_RemoveUnusedImportsKeepsUsedVersionSuffixedImport(t *testing.T) {
+	filePath := writeTempGoFile(t, `package main
+
+import (
+	"github.com/go-pg/pg/v9"
+)
+
+func main() {
+	_ = pg.Options{}
+}
+`)
+
+	formatted, _, err := Execute(fsys.New(), "github.com/example/project", filePath, "", "", OptionRemoveUnusedImports)
+	if err != nil {
+		t.Fatalf("Execute returned error: %s", err)
+	}
+
+	if !strings.Contains(string(formatted), `"github.com/go-pg/pg/v9"`) {
+		t.Fatalf("expected in-use versioned import to survive -rm-unused, got:\n%s", formatted)
+	}
+}
+
+func TestExecute_PreservesLeadingDocComment(t *testing.T) {
+	filePath := writeTempGoFile(t, `package main
+
+import (
+	"fmt"
+
+	// pq registers the postgres driver as a side effect.
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	fmt.Println("ok")
+}
+`)
+
+	formatted, _, err := Execute(fsys.New(), "github.com/example/project", filePath, "", "")
+	if err != nil {
+		t.Fatalf("Execute returned error: %s", err)
+	}
+
+	out := string(formatted)
+	if !strings.Contains(out, "// pq registers the postgres driver as a side effect.") {
+		t.Fatalf("expected leading doc comment to survive, got:\n%s", out)
+	}
+
+	docIdx := strings.Index(out, "// pq registers")
+	importIdx := strings.Index(out, `_ "github.com/lib/pq"`)
+	if docIdx == -1 || importIdx == -1 || docIdx > importIdx {
+		t.Fatalf("expected doc comment immediately before its import, got:\n%s", out)
+	}
+}